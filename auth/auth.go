@@ -0,0 +1,185 @@
+// Package auth resolves registry credentials the same way the Docker CLI
+// does: modern credential helpers and credsStore from ~/.docker/config.json,
+// falling back to the legacy ~/.dockercfg format.
+package auth
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/fsouza/go-dockerclient"
+)
+
+// Config is a resolved set of credentials for a single registry.
+type Config struct {
+	Username string
+	Password string
+}
+
+// Resolver resolves credentials for a registry host. Implementations may
+// consult local config files, credential helpers, or remote services such
+// as a Vault instance or a cloud provider's instance metadata service.
+type Resolver interface {
+	ResolveAuth(registry string) (Config, error)
+}
+
+// dockerConfigFile mirrors the subset of ~/.docker/config.json this package
+// understands.
+type dockerConfigFile struct {
+	Auths       map[string]authEntry `json:"auths"`
+	CredsStore  string               `json:"credsStore"`
+	CredHelpers map[string]string    `json:"credHelpers"`
+}
+
+type authEntry struct {
+	Auth string `json:"auth"`
+}
+
+// credHelperOutput is the JSON shape printed on stdout by every
+// docker-credential-* helper in response to a "get" request.
+type credHelperOutput struct {
+	ServerURL string `json:"ServerURL"`
+	Username  string `json:"Username"`
+	Secret    string `json:"Secret"`
+}
+
+// DefaultResolver resolves credentials the way the Docker CLI does: config.json
+// credHelpers, then credsStore, then an inline auths entry, finally falling
+// back to the legacy ~/.dockercfg format.
+type DefaultResolver struct {
+	// ConfigPath is the path to config.json. Defaults to
+	// $DOCKER_CONFIG/config.json or ~/.docker/config.json.
+	ConfigPath string
+}
+
+// NewDefaultResolver returns a DefaultResolver reading from the standard
+// Docker config locations.
+func NewDefaultResolver() *DefaultResolver {
+	return &DefaultResolver{ConfigPath: defaultConfigPath()}
+}
+
+func defaultConfigPath() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return filepath.Join(dir, "config.json")
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, ".docker", "config.json")
+}
+
+// ResolveAuth resolves credentials for registry, trying, in order: a
+// credHelpers entry, the top-level credsStore, an inline base64 auths entry,
+// and finally the legacy ~/.dockercfg file.
+func (r *DefaultResolver) ResolveAuth(registry string) (Config, error) {
+	key := normalizeRegistry(registry)
+
+	path := r.ConfigPath
+	if path == "" {
+		path = defaultConfigPath()
+	}
+
+	cfg, err := loadDockerConfig(path)
+	if err != nil {
+		return legacyAuth(registry), nil
+	}
+
+	if helper, ok := cfg.CredHelpers[key]; ok {
+		return runCredHelper(helper, key)
+	}
+
+	if _, hasEntry := cfg.Auths[key]; !hasEntry && cfg.CredsStore != "" {
+		return runCredHelper(cfg.CredsStore, key)
+	}
+
+	if entry, ok := cfg.Auths[key]; ok && entry.Auth != "" {
+		return decodeAuthEntry(entry)
+	}
+
+	return legacyAuth(registry), nil
+}
+
+func loadDockerConfig(path string) (*dockerConfigFile, error) {
+	if path == "" {
+		return nil, os.ErrNotExist
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg dockerConfigFile
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+func decodeAuthEntry(entry authEntry) (Config, error) {
+	decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+	if err != nil {
+		return Config{}, fmt.Errorf("decoding auth entry: %w", err)
+	}
+
+	username, password, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return Config{}, fmt.Errorf("malformed auth entry")
+	}
+
+	return Config{Username: username, Password: password}, nil
+}
+
+func runCredHelper(helper, serverURL string) (Config, error) {
+	cmd := exec.Command("docker-credential-"+helper, "get")
+	cmd.Stdin = strings.NewReader(serverURL)
+
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+
+	if err := cmd.Run(); err != nil {
+		return Config{}, fmt.Errorf("running docker-credential-%s: %w", helper, err)
+	}
+
+	var out credHelperOutput
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return Config{}, fmt.Errorf("decoding docker-credential-%s output: %w", helper, err)
+	}
+
+	return Config{Username: out.Username, Password: out.Secret}, nil
+}
+
+func legacyAuth(registry string) Config {
+	authConfigurations, err := docker.NewAuthConfigurationsFromDockerCfg()
+	if err != nil {
+		return Config{}
+	}
+
+	authConfiguration, ok := authConfigurations.Configs[registry]
+	if !ok {
+		return Config{}
+	}
+
+	return Config{Username: authConfiguration.Username, Password: authConfiguration.Password}
+}
+
+// normalizeRegistry maps a reference.Domain() result onto the key Docker
+// itself uses in config.json, where the Docker Hub entry is keyed by its
+// legacy index URL rather than "docker.io".
+func normalizeRegistry(registry string) string {
+	if registry == "" || registry == "docker.io" || registry == "registry-1.docker.io" {
+		return "index.docker.io/v1/"
+	}
+
+	return registry
+}
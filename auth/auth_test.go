@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestNormalizeRegistry(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{"", "index.docker.io/v1/"},
+		{"docker.io", "index.docker.io/v1/"},
+		{"registry-1.docker.io", "index.docker.io/v1/"},
+		{"quay.io", "quay.io"},
+		{"my.registry.example.com", "my.registry.example.com"},
+	}
+
+	for _, c := range cases {
+		if got := normalizeRegistry(c.in); got != c.want {
+			t.Errorf("normalizeRegistry(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("writing config.json: %v", err)
+	}
+
+	return path
+}
+
+func TestResolveAuth_InlineAuths(t *testing.T) {
+	auth := base64.StdEncoding.EncodeToString([]byte("alice:hunter2"))
+	path := writeConfig(t, `{"auths": {"quay.io": {"auth": "`+auth+`"}}}`)
+
+	resolver := &DefaultResolver{ConfigPath: path}
+
+	cfg, err := resolver.ResolveAuth("quay.io")
+	if err != nil {
+		t.Fatalf("ResolveAuth returned error: %v", err)
+	}
+	if cfg.Username != "alice" || cfg.Password != "hunter2" {
+		t.Errorf("ResolveAuth = %+v, want Username=alice Password=hunter2", cfg)
+	}
+}
+
+func TestResolveAuth_NoConfig(t *testing.T) {
+	resolver := &DefaultResolver{ConfigPath: filepath.Join(t.TempDir(), "missing.json")}
+
+	cfg, err := resolver.ResolveAuth("quay.io")
+	if err != nil {
+		t.Fatalf("ResolveAuth returned error: %v", err)
+	}
+	if cfg != (Config{}) {
+		t.Errorf("ResolveAuth = %+v, want zero value", cfg)
+	}
+}
+
+func TestResolveAuth_CredHelper(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("credential helper shell script is unix-specific")
+	}
+
+	helperDir := t.TempDir()
+	helperScript := `#!/bin/sh
+read server
+echo "{\"ServerURL\": \"$server\", \"Username\": \"helper-user\", \"Secret\": \"helper-pass\"}"
+`
+	helperPath := filepath.Join(helperDir, "docker-credential-test")
+	if err := os.WriteFile(helperPath, []byte(helperScript), 0o700); err != nil {
+		t.Fatalf("writing fake credential helper: %v", err)
+	}
+
+	t.Setenv("PATH", helperDir+":"+os.Getenv("PATH"))
+
+	path := writeConfig(t, `{"credHelpers": {"my.registry.example.com": "test"}}`)
+	resolver := &DefaultResolver{ConfigPath: path}
+
+	cfg, err := resolver.ResolveAuth("my.registry.example.com")
+	if err != nil {
+		t.Fatalf("ResolveAuth returned error: %v", err)
+	}
+	if cfg.Username != "helper-user" || cfg.Password != "helper-pass" {
+		t.Errorf("ResolveAuth = %+v, want Username=helper-user Password=helper-pass", cfg)
+	}
+}
+
+func TestResolveAuth_CredsStoreFallback(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("credential helper shell script is unix-specific")
+	}
+
+	helperDir := t.TempDir()
+	helperScript := `#!/bin/sh
+read server
+echo "{\"ServerURL\": \"$server\", \"Username\": \"store-user\", \"Secret\": \"store-pass\"}"
+`
+	helperPath := filepath.Join(helperDir, "docker-credential-store")
+	if err := os.WriteFile(helperPath, []byte(helperScript), 0o700); err != nil {
+		t.Fatalf("writing fake credential helper: %v", err)
+	}
+
+	t.Setenv("PATH", helperDir+":"+os.Getenv("PATH"))
+
+	// No explicit "auths" entry for this registry, so credsStore applies.
+	path := writeConfig(t, `{"credsStore": "store", "auths": {}}`)
+	resolver := &DefaultResolver{ConfigPath: path}
+
+	cfg, err := resolver.ResolveAuth("quay.io")
+	if err != nil {
+		t.Fatalf("ResolveAuth returned error: %v", err)
+	}
+	if cfg.Username != "store-user" || cfg.Password != "store-pass" {
+		t.Errorf("ResolveAuth = %+v, want Username=store-user Password=store-pass", cfg)
+	}
+}
@@ -0,0 +1,117 @@
+package contman
+
+import "io"
+
+// Config describes how a container should be created by a ContainerManager.
+type Config struct {
+	Image string
+	// Cmd is run as `sh -c Cmd`, preserving this package's original
+	// behavior. Ignored if Entrypoint or Args is set.
+	Cmd string
+	// Entrypoint and Args are passed to the backend as-is, with no implicit
+	// "sh -c" wrapping, for callers that need to invoke a binary directly.
+	// Set either one to opt out of the Cmd/"sh -c" behavior; a nil
+	// Entrypoint with Args set leaves the image's own entrypoint in place.
+	Entrypoint []string
+	Args       []string
+	Env        map[string]string
+	Mounts     []Mount
+
+	// NetworkMode selects the container's network mode: "host", "bridge",
+	// "none", "container:<id>", or a custom network name. The zero value
+	// defaults to "host" for backward compatibility with existing callers.
+	NetworkMode string
+
+	User           string
+	CapAdd         []string
+	CapDrop        []string
+	ReadonlyRootfs bool
+	// SecurityOpt sets backend security options such as seccomp/apparmor
+	// profiles or "no-new-privileges".
+	SecurityOpt []string
+	Resources   Resources
+
+	// Rootless requests a rootless container on backends that support it
+	// (e.g. Podman). It is ignored by backends that have no such concept.
+	// On Podman it also sets the network mode to slirp4netns, overriding
+	// NetworkMode.
+	Rootless bool
+	// UIDMappings and GIDMappings configure the rootless UID/GID mapping
+	// used when Rootless is set. Backends without rootless support ignore
+	// them.
+	UIDMappings []IDMap
+	GIDMappings []IDMap
+}
+
+// Resources caps the resources a container may consume. A zero value
+// applies no limit.
+type Resources struct {
+	CPUShares int64
+	Memory    int64
+	PidsLimit int64
+}
+
+// IDMap describes a single entry of a UID or GID mapping used for rootless
+// containers.
+type IDMap struct {
+	ContainerID int
+	HostID      int
+	Size        int
+}
+
+// MountType selects the kind of mount a Mount describes.
+type MountType string
+
+const (
+	// MountTypeBind bind-mounts Source from the host. It is the zero value,
+	// matching this package's original bind-mount-only behavior.
+	MountTypeBind MountType = "bind"
+	// MountTypeVolume mounts a named volume.
+	MountTypeVolume MountType = "volume"
+	// MountTypeTmpfs mounts an in-memory tmpfs; Source is ignored.
+	MountTypeTmpfs MountType = "tmpfs"
+)
+
+// Mount describes a single mount passed through to a container.
+type Mount struct {
+	Source   string
+	Target   string
+	ReadOnly bool
+	// Type selects the kind of mount. The zero value is MountTypeBind.
+	Type MountType
+}
+
+// Container represents a container created by a ContainerManager.
+type Container interface {
+	ID() string
+	Start() error
+	Stop() error
+	Wait() (int, error)
+	Remove() error
+}
+
+// PullEvent is a single structured progress update emitted while an image is
+// being pulled. Status mirrors the Docker daemon's per-layer status string
+// (e.g. "Downloading", "Extracting"); Current/Total are byte counts when the
+// daemon reports progress detail, and Error is set on the final event if the
+// pull failed.
+type PullEvent struct {
+	ID      string
+	Status  string
+	Current int64
+	Total   int64
+	Error   error
+}
+
+// ContainerManager abstracts over a container runtime (Docker, Podman, ...).
+type ContainerManager interface {
+	// PullImage pulls image, writing human-readable progress to os.Stdout.
+	PullImage(image string) error
+	// PullImageWithProgress pulls image, writing rendered progress to w and
+	// invoking handler with a structured PullEvent for every message in the
+	// pull stream. handler may be nil, in which case only w is used.
+	PullImageWithProgress(image string, w io.Writer, handler func(PullEvent)) error
+	HasImage(image string) bool
+	ContainerCreate(config Config) (Container, error)
+	GetSystemMounts() []Mount
+}
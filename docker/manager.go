@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -17,19 +18,39 @@ import (
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/client"
-	"github.com/fsouza/go-dockerclient"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/docker/docker/pkg/term"
 
 	"github.com/elemir/contman"
+	"github.com/elemir/contman/auth"
 )
 
+func init() {
+	contman.Register("docker", func() (contman.ContainerManager, error) {
+		return NewDockerManager()
+	})
+}
+
 type DockerManager struct {
-	client  *client.Client
-	context context.Context
-	mutex   *sync.Mutex
-	cancel  context.CancelFunc
+	client       *client.Client
+	context      context.Context
+	mutex        *sync.Mutex
+	cancel       context.CancelFunc
+	authResolver auth.Resolver
+	trustPolicy  contman.TrustPolicy
+
+	exposeDockerSocket bool
 }
 
 func NewDockerManager() (*DockerManager, error) {
+	return NewDockerManagerWithAuth(auth.NewDefaultResolver())
+}
+
+// NewDockerManagerWithAuth builds a DockerManager that resolves registry
+// credentials through resolver instead of the default config.json/dockercfg
+// lookup, allowing callers to plug in their own credential sources (e.g.
+// Vault, a cloud provider's instance metadata service).
+func NewDockerManagerWithAuth(resolver auth.Resolver) (*DockerManager, error) {
 	cli, err := client.NewEnvClient()
 	if err != nil {
 		return nil, err
@@ -40,22 +61,34 @@ func NewDockerManager() (*DockerManager, error) {
 	cli.NegotiateAPIVersion(ctx)
 
 	dm := &DockerManager{
-		client:  cli,
-		context: ctx,
-		mutex:   &sync.Mutex{},
-		cancel:  cancel,
+		client:       cli,
+		context:      ctx,
+		mutex:        &sync.Mutex{},
+		cancel:       cancel,
+		authResolver: resolver,
+		trustPolicy:  contman.Disabled(),
 	}
 
 	return dm, nil
 }
 
 func (dm *DockerManager) PullImage(image string) error {
+	return dm.PullImageWithProgress(image, os.Stdout, nil)
+}
+
+// PullImageWithProgress pulls image, rendering progress to w via
+// jsonmessage.DisplayJSONMessagesStream (as live progress bars if w is a
+// terminal) while also decoding the same stream into structured
+// contman.PullEvent values passed to handler, which may be nil. The two are
+// driven off a teed copy of the daemon's JSON message stream so both always
+// run, regardless of whether a handler is supplied.
+func (dm *DockerManager) PullImageWithProgress(image string, w io.Writer, handler func(contman.PullEvent)) error {
 	named, err := reference.ParseNormalizedNamed(image)
 	if err != nil {
 		log.WithError(err).Error("Cannot parse image name")
 		return err
 	}
-	authConfig := getAuthConfig(reference.Domain(named))
+	authConfig := dm.getAuthConfig(reference.Domain(named))
 	encodedJSON, err := json.Marshal(authConfig)
 	if err != nil {
 		log.WithError(err).Error("Error encoding auth config")
@@ -69,8 +102,73 @@ func (dm *DockerManager) PullImage(image string) error {
 		return err
 	}
 	defer func() { _ = out.Close() }()
-	_, _ = io.Copy(os.Stdout, out)
-	return nil
+
+	pr, pw := io.Pipe()
+	fd, isTerminal := term.GetFdInfo(w)
+
+	renderDone := make(chan error, 1)
+	go func() {
+		renderDone <- jsonmessage.DisplayJSONMessagesStream(pr, w, fd, isTerminal, nil)
+		// DisplayJSONMessagesStream can return before consuming the rest of
+		// pr (e.g. it stops as soon as it sees an error message). Keep
+		// draining afterward so a pending write on the io.Pipe from
+		// decodePullEvents' TeeReader - which may still want a few more
+		// bytes to finish decoding - never blocks forever on a reader that
+		// has stopped reading.
+		_, _ = io.Copy(io.Discard, pr)
+	}()
+
+	decodeErr := decodePullEvents(io.TeeReader(out, pw), handler)
+	_ = pw.Close()
+
+	if renderErr := <-renderDone; renderErr != nil && decodeErr == nil {
+		decodeErr = renderErr
+	}
+
+	if decodeErr != nil {
+		return decodeErr
+	}
+
+	return dm.checkTrust(image)
+}
+
+// decodePullEvents decodes r as the daemon's newline-delimited JSON message
+// stream, invoking handler with a structured contman.PullEvent for each
+// message; handler may be nil, in which case the stream is merely drained
+// (its bytes still reach any reader teed off r) so the pull's own error, if
+// any, is still surfaced.
+func decodePullEvents(r io.Reader, handler func(contman.PullEvent)) error {
+	decoder := json.NewDecoder(r)
+	for {
+		var msg jsonmessage.JSONMessage
+		if err := decoder.Decode(&msg); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			log.WithError(err).Error("Error decoding pull progress")
+			return err
+		}
+
+		event := contman.PullEvent{
+			ID:     msg.ID,
+			Status: msg.Status,
+		}
+		if msg.Progress != nil {
+			event.Current = msg.Progress.Current
+			event.Total = msg.Progress.Total
+		}
+		if msg.Error != nil {
+			event.Error = errors.New(msg.Error.Message)
+		}
+
+		if handler != nil {
+			handler(event)
+		}
+
+		if event.Error != nil {
+			return event.Error
+		}
+	}
 }
 
 func (dm *DockerManager) HasImage(image string) bool {
@@ -108,13 +206,20 @@ func (dm *DockerManager) ContainerCreate(config contman.Config) (contman.Contain
 		return nil, err
 	}
 
+	if err := dm.checkTrust(config.Image); err != nil {
+		return nil, err
+	}
+
 	mounts := make([]mount.Mount, len(config.Mounts))
 	for i, m := range config.Mounts {
 		mounts[i] = mount.Mount{
 			Source:   m.Source,
 			Target:   m.Target,
 			ReadOnly: m.ReadOnly,
-			Type:     mount.TypeBind,
+			Type:     mountType(m.Type),
+		}
+		if m.Type == contman.MountTypeTmpfs {
+			mounts[i].Source = ""
 		}
 	}
 
@@ -125,20 +230,41 @@ func (dm *DockerManager) ContainerCreate(config contman.Config) (contman.Contain
 		i++
 	}
 
+	networkMode := config.NetworkMode
+	if networkMode == "" {
+		networkMode = "host"
+	}
+
+	var resources container.Resources
+	if config.Resources.CPUShares != 0 {
+		resources.CPUShares = config.Resources.CPUShares
+	}
+	if config.Resources.Memory != 0 {
+		resources.Memory = config.Resources.Memory
+	}
+	if config.Resources.PidsLimit != 0 {
+		resources.PidsLimit = &config.Resources.PidsLimit
+	}
+
+	entrypoint, cmd := entrypointAndCmd(config)
+
 	resp, err := dm.client.ContainerCreate(dm.context,
 		&container.Config{
 			Image:      config.Image,
-			Entrypoint: []string{"sh"},
-			Cmd: []string{
-				"-c",
-				config.Cmd,
-			},
+			Entrypoint: entrypoint,
+			Cmd:        cmd,
 			WorkingDir: wd,
 			Env:        env,
+			User:       config.User,
 		},
 		&container.HostConfig{
-			Mounts:      mounts,
-			NetworkMode: "host",
+			Mounts:         mounts,
+			NetworkMode:    container.NetworkMode(networkMode),
+			CapAdd:         config.CapAdd,
+			CapDrop:        config.CapDrop,
+			ReadonlyRootfs: config.ReadonlyRootfs,
+			SecurityOpt:    config.SecurityOpt,
+			Resources:      resources,
 		},
 		nil,
 		"",
@@ -155,7 +281,37 @@ func (dm *DockerManager) ContainerCreate(config contman.Config) (contman.Contain
 	}, nil
 }
 
+// entrypointAndCmd returns the raw Entrypoint/Args from config if either is
+// set, otherwise falls back to this package's original "sh -c Cmd" wrapping
+// so that existing callers setting only Cmd keep working unchanged.
+func entrypointAndCmd(config contman.Config) ([]string, []string) {
+	if config.Entrypoint != nil || config.Args != nil {
+		return config.Entrypoint, config.Args
+	}
+
+	return []string{"sh"}, []string{"-c", config.Cmd}
+}
+
+func mountType(t contman.MountType) mount.Type {
+	switch t {
+	case contman.MountTypeVolume:
+		return mount.TypeVolume
+	case contman.MountTypeTmpfs:
+		return mount.TypeTmpfs
+	default:
+		return mount.TypeBind
+	}
+}
+
+// GetSystemMounts returns the host mounts (docker.sock, ~/.docker) needed to
+// run Docker-in-Docker. It returns nil unless opted into via
+// WithDockerSocketPassthrough, so the library is safe to use outside that
+// build-agent scenario.
 func (dm *DockerManager) GetSystemMounts() []contman.Mount {
+	if !dm.exposeDockerSocket {
+		return nil
+	}
+
 	return []contman.Mount{
 		{
 			Source: "/var/run/docker.sock",
@@ -169,19 +325,22 @@ func (dm *DockerManager) GetSystemMounts() []contman.Mount {
 	}
 }
 
-func getAuthConfig(registry string) *types.AuthConfig {
-	authConfigurations, err := docker.NewAuthConfigurationsFromDockerCfg()
-	if err != nil {
-		return &types.AuthConfig{}
-	}
+// WithDockerSocketPassthrough opts into GetSystemMounts exposing the Docker
+// socket and credential directory, for Docker-in-Docker build-agent use.
+func (dm *DockerManager) WithDockerSocketPassthrough() *DockerManager {
+	dm.exposeDockerSocket = true
+	return dm
+}
 
-	authConfiguration, ok := authConfigurations.Configs[registry]
-	if !ok {
+func (dm *DockerManager) getAuthConfig(registry string) *types.AuthConfig {
+	resolved, err := dm.authResolver.ResolveAuth(registry)
+	if err != nil {
+		log.WithError(err).WithField("registry", registry).Error("Error resolving registry credentials")
 		return &types.AuthConfig{}
 	}
 
 	return &types.AuthConfig{
-		Username: authConfiguration.Username,
-		Password: authConfiguration.Password,
+		Username: resolved.Username,
+		Password: resolved.Password,
 	}
-}
\ No newline at end of file
+}
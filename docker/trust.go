@@ -0,0 +1,29 @@
+package docker
+
+import "github.com/elemir/contman"
+
+// WithTrustPolicy configures the content-trust policy enforced on every
+// subsequent PullImage/PullImageWithProgress and ContainerCreate call. The
+// zero value (contman.Disabled()) performs no verification.
+func (dm *DockerManager) WithTrustPolicy(policy contman.TrustPolicy) *DockerManager {
+	dm.trustPolicy = policy
+	return dm
+}
+
+// checkTrust enforces dm.trustPolicy against image. TrustDisabled is the
+// only mode with a real implementation (see trust.go); any other mode fails
+// closed with ErrUntrustedImage rather than approximate a verification this
+// package cannot actually perform. Both PullImageWithProgress and
+// ContainerCreate call this directly, so the policy is enforced even for an
+// image that was never pulled through this package (e.g. already present
+// locally via HasImage).
+func (dm *DockerManager) checkTrust(image string) error {
+	if dm.trustPolicy.Mode == contman.TrustDisabled {
+		return nil
+	}
+
+	return &contman.ErrUntrustedImage{
+		Image:  image,
+		Reason: "no TrustMode besides TrustDisabled is implemented yet; refusing to trust the image rather than approximate it",
+	}
+}
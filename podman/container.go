@@ -0,0 +1,83 @@
+package podman
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// PodmanContainer is a contman.Container backed by a libpod container ID.
+type PodmanContainer struct {
+	manager *PodmanManager
+	id      string
+}
+
+func (c *PodmanContainer) ID() string {
+	return c.id
+}
+
+func (c *PodmanContainer) Start() error {
+	return c.action("start")
+}
+
+func (c *PodmanContainer) Stop() error {
+	return c.action("stop")
+}
+
+func (c *PodmanContainer) Remove() error {
+	return c.action("")
+}
+
+// Wait blocks until the container exits and returns its exit code.
+func (c *PodmanContainer) Wait() (int, error) {
+	req, err := http.NewRequestWithContext(c.manager.context, http.MethodPost,
+		c.manager.url(fmt.Sprintf("/containers/%s/wait", c.id)), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := c.manager.client.Do(req)
+	if err != nil {
+		log.WithError(err).Error("Error waiting for container")
+		return 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var exitCode int
+	if err := json.NewDecoder(resp.Body).Decode(&exitCode); err != nil {
+		return 0, err
+	}
+
+	return exitCode, nil
+}
+
+func (c *PodmanContainer) action(verb string) error {
+	path := fmt.Sprintf("/containers/%s", c.id)
+	method := http.MethodPost
+
+	if verb == "" {
+		method = http.MethodDelete
+	} else {
+		path += "/" + verb
+	}
+
+	req, err := http.NewRequestWithContext(c.manager.context, method, c.manager.url(path), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.manager.client.Do(req)
+	if err != nil {
+		log.WithError(err).WithField("container", c.id).Error("Error performing container action")
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("podman: container %s: unexpected status %s", c.id, resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,332 @@
+// Package podman implements contman.ContainerManager against Podman's libpod
+// REST API over its unix socket, for environments that run rootless Podman
+// instead of (or alongside) the Docker daemon.
+package podman
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/elemir/contman"
+)
+
+func init() {
+	contman.Register("podman", func() (contman.ContainerManager, error) {
+		return NewPodmanManager()
+	})
+}
+
+const apiPrefix = "/v4.0.0/libpod"
+
+type PodmanManager struct {
+	client  *http.Client
+	context context.Context
+	mutex   *sync.Mutex
+	cancel  context.CancelFunc
+	socket  string
+	// baseURL is the scheme+host prefixed to apiPrefix by url(). It is
+	// always "http://d" in production (the unix socket dial target is
+	// fixed by client.Transport, and the host is otherwise ignored); tests
+	// override it to point at an httptest.Server instead.
+	baseURL string
+
+	exposeSocket bool
+}
+
+// WithSocketPassthrough opts into GetSystemMounts exposing the Podman socket
+// and auth.json, for Podman-in-Podman build-agent use.
+func (pm *PodmanManager) WithSocketPassthrough() *PodmanManager {
+	pm.exposeSocket = true
+	return pm
+}
+
+// NewPodmanManager connects to the Podman socket at $XDG_RUNTIME_DIR/podman/podman.sock
+// or /run/podman/podman.sock, whichever exists.
+func NewPodmanManager() (*PodmanManager, error) {
+	socket, err := socketPath()
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &PodmanManager{
+		client:  &http.Client{Transport: transport},
+		context: ctx,
+		mutex:   &sync.Mutex{},
+		cancel:  cancel,
+		socket:  socket,
+		baseURL: "http://d",
+	}, nil
+}
+
+func socketPath() (string, error) {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		path := filepath.Join(dir, "podman", "podman.sock")
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+
+	if _, err := os.Stat("/run/podman/podman.sock"); err == nil {
+		return "/run/podman/podman.sock", nil
+	}
+
+	return "", fmt.Errorf("podman: no podman.sock found under $XDG_RUNTIME_DIR or /run/podman")
+}
+
+func (pm *PodmanManager) url(path string) string {
+	return pm.baseURL + apiPrefix + path
+}
+
+func (pm *PodmanManager) PullImage(image string) error {
+	return pm.PullImageWithProgress(image, os.Stdout, nil)
+}
+
+// PullImageWithProgress pulls image via POST /libpod/images/pull, decoding
+// the newline-delimited JSON stream libpod returns and invoking handler with
+// a structured contman.PullEvent per message. handler may be nil.
+func (pm *PodmanManager) PullImageWithProgress(image string, w io.Writer, handler func(contman.PullEvent)) error {
+	req, err := http.NewRequestWithContext(pm.context, http.MethodPost,
+		pm.url(fmt.Sprintf("/images/pull?reference=%s", image)), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := pm.client.Do(req)
+	if err != nil {
+		log.WithError(err).Error("Error pulling image")
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var msg struct {
+			Stream string `json:"stream"`
+			Error  string `json:"error"`
+			ID     string `json:"id"`
+		}
+
+		if err := decoder.Decode(&msg); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			log.WithError(err).Error("Error decoding pull progress")
+			return err
+		}
+
+		if msg.Stream != "" {
+			if _, err := io.WriteString(w, msg.Stream); err != nil {
+				return err
+			}
+		}
+
+		if handler == nil {
+			if msg.Error != "" {
+				return errors.New(msg.Error)
+			}
+			continue
+		}
+
+		event := contman.PullEvent{ID: msg.ID, Status: strings.TrimSpace(msg.Stream)}
+		if msg.Error != "" {
+			event.Error = errors.New(msg.Error)
+		}
+
+		handler(event)
+
+		if event.Error != nil {
+			return event.Error
+		}
+	}
+}
+
+func (pm *PodmanManager) HasImage(image string) bool {
+	if image == "" {
+		return false
+	}
+	if !strings.Contains(image, ":") {
+		image += ":latest"
+	}
+
+	req, err := http.NewRequestWithContext(pm.context, http.MethodGet,
+		pm.url("/images/"+image+"/exists"), nil)
+	if err != nil {
+		return false
+	}
+
+	resp, err := pm.client.Do(req)
+	if err != nil {
+		log.WithError(err).Error("Unable to check image presence")
+		return false
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	return resp.StatusCode == http.StatusNoContent
+}
+
+func (pm *PodmanManager) ContainerCreate(config contman.Config) (contman.Container, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	mounts := make([]map[string]interface{}, len(config.Mounts))
+	for i, m := range config.Mounts {
+		mounts[i] = map[string]interface{}{
+			"Source":      m.Source,
+			"Destination": m.Target,
+			"Type":        podmanMountType(m.Type),
+			"RW":          !m.ReadOnly,
+		}
+	}
+
+	networkMode := config.NetworkMode
+	if networkMode == "" {
+		networkMode = "host"
+	}
+
+	entrypoint, command := entrypointAndCommand(config)
+
+	body := map[string]interface{}{
+		"image":      config.Image,
+		"entrypoint": entrypoint,
+		"command":    command,
+		"work_dir":   wd,
+		"env":        config.Env,
+		"mounts":     mounts,
+		"netns":      map[string]string{"nsmode": networkMode},
+		"user":       config.User,
+		"cap_add":    config.CapAdd,
+		"cap_drop":   config.CapDrop,
+		"read_only":  config.ReadonlyRootfs,
+	}
+
+	if config.Rootless {
+		body["netns"] = map[string]string{"nsmode": "slirp4netns"}
+		body["idmappings"] = map[string]interface{}{
+			"UIDMap": toPodmanIDMap(config.UIDMappings),
+			"GIDMap": toPodmanIDMap(config.GIDMappings),
+		}
+	}
+
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(pm.context, http.MethodPost,
+		pm.url("/containers/create"), bytes.NewReader(encoded))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := pm.client.Do(req)
+	if err != nil {
+		log.WithError(err).Error("Error creating container")
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&apiErr)
+		return nil, fmt.Errorf("podman: creating container: %s: %s", resp.Status, apiErr.Message)
+	}
+
+	var created struct {
+		ID string `json:"Id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return nil, err
+	}
+
+	return &PodmanContainer{manager: pm, id: created.ID}, nil
+}
+
+// entrypointAndCommand returns the raw Entrypoint/Args from config if
+// either is set, otherwise falls back to this package's original
+// "sh -c Cmd" wrapping so that existing callers setting only Cmd keep
+// working unchanged.
+func entrypointAndCommand(config contman.Config) ([]string, []string) {
+	if config.Entrypoint != nil || config.Args != nil {
+		return config.Entrypoint, config.Args
+	}
+
+	return []string{"sh"}, []string{"-c", config.Cmd}
+}
+
+func podmanMountType(t contman.MountType) string {
+	switch t {
+	case contman.MountTypeVolume:
+		return "volume"
+	case contman.MountTypeTmpfs:
+		return "tmpfs"
+	default:
+		return "bind"
+	}
+}
+
+// GetSystemMounts returns the host mounts (podman.sock, auth.json) needed to
+// run Podman-in-Podman. It returns nil unless opted into via
+// WithSocketPassthrough, so the library is safe to use outside that
+// build-agent scenario.
+func (pm *PodmanManager) GetSystemMounts() []contman.Mount {
+	if !pm.exposeSocket {
+		return nil
+	}
+
+	mounts := []contman.Mount{
+		{
+			Source: pm.socket,
+			Target: "/run/podman/podman.sock",
+		},
+	}
+
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		authFile := filepath.Join(dir, "containers", "auth.json")
+		mounts = append(mounts, contman.Mount{
+			Source:   authFile,
+			Target:   authFile,
+			ReadOnly: true,
+		})
+	}
+
+	return mounts
+}
+
+func toPodmanIDMap(mappings []contman.IDMap) []map[string]int {
+	out := make([]map[string]int, len(mappings))
+	for i, m := range mappings {
+		out[i] = map[string]int{
+			"container_id": m.ContainerID,
+			"host_id":      m.HostID,
+			"size":         m.Size,
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,128 @@
+package podman
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/elemir/contman"
+)
+
+func testManager(t *testing.T, srv *httptest.Server) *PodmanManager {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	return &PodmanManager{
+		client:  srv.Client(),
+		context: ctx,
+		mutex:   &sync.Mutex{},
+		cancel:  cancel,
+		baseURL: srv.URL,
+	}
+}
+
+func TestHasImage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v4.0.0/libpod/images/present:latest/exists" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	pm := testManager(t, srv)
+
+	if !pm.HasImage("present") {
+		t.Error("expected HasImage(\"present\") to be true")
+	}
+	if pm.HasImage("missing") {
+		t.Error("expected HasImage(\"missing\") to be false")
+	}
+	if pm.HasImage("") {
+		t.Error("expected HasImage(\"\") to be false")
+	}
+}
+
+func TestContainerCreate_Success(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"Id": "abc123"}`))
+	}))
+	defer srv.Close()
+
+	pm := testManager(t, srv)
+
+	container, err := pm.ContainerCreate(contman.Config{Image: "busybox", Cmd: "true"})
+	if err != nil {
+		t.Fatalf("ContainerCreate returned error: %v", err)
+	}
+	if container.ID() != "abc123" {
+		t.Errorf("ID() = %q, want %q", container.ID(), "abc123")
+	}
+}
+
+func TestContainerCreate_ErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"cause": "no such image", "message": "no such image: busybox"}`))
+	}))
+	defer srv.Close()
+
+	pm := testManager(t, srv)
+
+	_, err := pm.ContainerCreate(contman.Config{Image: "busybox", Cmd: "true"})
+	if err == nil {
+		t.Fatal("expected ContainerCreate to return an error on a non-2xx response")
+	}
+}
+
+func TestPodmanMountType(t *testing.T) {
+	cases := []struct {
+		in   contman.MountType
+		want string
+	}{
+		{contman.MountTypeBind, "bind"},
+		{contman.MountTypeVolume, "volume"},
+		{contman.MountTypeTmpfs, "tmpfs"},
+		{"", "bind"},
+	}
+
+	for _, c := range cases {
+		if got := podmanMountType(c.in); got != c.want {
+			t.Errorf("podmanMountType(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestToPodmanIDMap(t *testing.T) {
+	in := []contman.IDMap{{ContainerID: 0, HostID: 1000, Size: 1}}
+
+	out := toPodmanIDMap(in)
+
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+	if out[0]["container_id"] != 0 || out[0]["host_id"] != 1000 || out[0]["size"] != 1 {
+		t.Errorf("toPodmanIDMap(%v) = %v", in, out)
+	}
+}
+
+func TestEntrypointAndCommand(t *testing.T) {
+	entrypoint, command := entrypointAndCommand(contman.Config{Cmd: "echo hi"})
+	if len(entrypoint) != 1 || entrypoint[0] != "sh" || len(command) != 2 || command[0] != "-c" || command[1] != "echo hi" {
+		t.Errorf("legacy Cmd wrapping: entrypoint=%v command=%v", entrypoint, command)
+	}
+
+	entrypoint, command = entrypointAndCommand(contman.Config{
+		Entrypoint: []string{"/bin/myapp"},
+		Args:       []string{"--flag"},
+	})
+	if len(entrypoint) != 1 || entrypoint[0] != "/bin/myapp" || len(command) != 1 || command[0] != "--flag" {
+		t.Errorf("raw exec: entrypoint=%v command=%v", entrypoint, command)
+	}
+}
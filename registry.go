@@ -0,0 +1,63 @@
+package contman
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Factory builds a ContainerManager for a single backend.
+type Factory func() (ContainerManager, error)
+
+var backends = map[string]Factory{}
+
+// Register makes a container runtime backend available under name for use
+// by New. It is meant to be called from the backend package's init(), e.g.
+// docker and podman register themselves as "docker" and "podman".
+func Register(name string, factory Factory) {
+	backends[name] = factory
+}
+
+// New builds a ContainerManager for the named runtime ("docker", "podman").
+// If runtime is empty, it auto-detects one, checking in order: CONTAINER_HOST
+// set selects Podman, DOCKER_HOST set selects Docker, a Podman socket at a
+// well-known path selects Podman, and otherwise Docker is used.
+func New(runtime string) (ContainerManager, error) {
+	if runtime == "" {
+		runtime = detectRuntime()
+	}
+
+	factory, ok := backends[runtime]
+	if !ok {
+		return nil, fmt.Errorf("contman: unknown runtime %q (is it imported?)", runtime)
+	}
+
+	return factory()
+}
+
+func detectRuntime() string {
+	if os.Getenv("CONTAINER_HOST") != "" {
+		return "podman"
+	}
+	if os.Getenv("DOCKER_HOST") != "" {
+		return "docker"
+	}
+
+	for _, sock := range podmanSockets() {
+		if _, err := os.Stat(sock); err == nil {
+			return "podman"
+		}
+	}
+
+	return "docker"
+}
+
+func podmanSockets() []string {
+	sockets := []string{"/run/podman/podman.sock"}
+
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		sockets = append([]string{filepath.Join(dir, "podman", "podman.sock")}, sockets...)
+	}
+
+	return sockets
+}
@@ -0,0 +1,42 @@
+package contman
+
+import "fmt"
+
+// TrustMode selects how PullImage verifies an image before it is used.
+type TrustMode int
+
+const (
+	// TrustDisabled performs no verification. It is the zero value so that
+	// a zero-value TrustPolicy is safe to use, and the only mode backed by
+	// a real implementation today: genuine Notary/DCT and cosign signature
+	// verification require calling out to a trusted, vetted client library,
+	// which no backend does yet. A prior attempt shipped a hand-rolled
+	// approximation of both that did no real cryptographic verification; it
+	// was removed rather than left in place as a security control that
+	// wasn't one.
+	TrustDisabled TrustMode = iota
+)
+
+// TrustPolicy configures the content-trust verification a ContainerManager
+// performs before an image is considered safe to run. Build one with
+// Disabled; there is no other mode yet (see TrustDisabled).
+type TrustPolicy struct {
+	Mode TrustMode
+}
+
+// Disabled returns a TrustPolicy that performs no verification.
+func Disabled() TrustPolicy {
+	return TrustPolicy{Mode: TrustDisabled}
+}
+
+// ErrUntrustedImage is returned when an image fails content-trust
+// verification.
+type ErrUntrustedImage struct {
+	Image  string
+	Digest string
+	Reason string
+}
+
+func (e *ErrUntrustedImage) Error() string {
+	return fmt.Sprintf("untrusted image %s@%s: %s", e.Image, e.Digest, e.Reason)
+}